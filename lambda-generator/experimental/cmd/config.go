@@ -0,0 +1,74 @@
+// Copyright © 2016 NAME HERE <EMAIL ADDRESS>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// olConfig is the small per-project config file at .openlambda/config. It
+// tracks the project's preferred frontend as well as whichever frontend
+// was used most recently, so a bare `--frontend`-less invocation keeps
+// working the way the user left it.
+type olConfig struct {
+	DefaultFrontend string `json:"default_frontend,omitempty"`
+	LastFrontend    string `json:"last_frontend,omitempty"`
+}
+
+// readFrontendConfig returns the frontend .openlambda/config says to use:
+// its explicit default_frontend if set, otherwise the last_frontend it
+// recorded. It returns "" if olDir has no config file or neither field is
+// set, letting the caller fall through to the compiled default.
+func readFrontendConfig(olDir string) string {
+	cfg, err := loadOlConfig(olDir)
+	if err != nil {
+		return ""
+	}
+	if cfg.DefaultFrontend != "" {
+		return cfg.DefaultFrontend
+	}
+	return cfg.LastFrontend
+}
+
+// writeFrontendConfig records name as the last frontend used in olDir, so
+// the next .openlambda/config-relative invocation defaults to it unless
+// default_frontend is set there.
+func writeFrontendConfig(olDir, name string) error {
+	cfg, err := loadOlConfig(olDir)
+	if err != nil {
+		cfg = &olConfig{}
+	}
+	cfg.LastFrontend = name
+
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(olDir, "config"), data, 0644)
+}
+
+func loadOlConfig(olDir string) (*olConfig, error) {
+	data, err := os.ReadFile(filepath.Join(olDir, "config"))
+	if err != nil {
+		return nil, err
+	}
+	var cfg olConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
@@ -0,0 +1,50 @@
+// Copyright © 2016 NAME HERE <EMAIL ADDRESS>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// frontendsCmd groups subcommands for inspecting the frontends this CLI
+// knows about, compiled-in or discovered as ol-frontend-* plugins.
+var frontendsCmd = &cobra.Command{
+	Use:   "frontends",
+	Short: "Inspect available OpenLambda frontend frameworks",
+}
+
+// frontendsListCmd prints every frontend the registry found, marking
+// plugins with their discovered path so it's clear which ones came from
+// $PATH/$OL_PLUGINS rather than being compiled into this binary.
+var frontendsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List compiled-in and discovered frontend frameworks",
+	Run: func(cmd *cobra.Command, args []string) {
+		for _, name := range registry.Names() {
+			if p, ok := registry.Plugin(name); ok && registry.IsPlugin(name) {
+				fmt.Printf("%s\t(plugin, version %s, %s)\n", name, p.Metadata.Version, p.Path)
+			} else {
+				fmt.Printf("%s\t(compiled-in)\n", name)
+			}
+		}
+	},
+}
+
+func init() {
+	frontendsCmd.AddCommand(frontendsListCmd)
+	RootCmd.AddCommand(frontendsCmd)
+}
@@ -25,10 +25,15 @@ import (
 	"github.com/tylerharter/open-lambda/lambda-generator/experimental/frontends/effe"
 )
 
+// defaultFrontend is the frontend compiled into this binary when nothing
+// else picks one.
+const defaultFrontend = "effe"
+
 var (
 	cfgFile     string
 	frontendStr string
 	fe          frontends.FrontEnd
+	registry    = frontends.NewRegistry()
 )
 
 // This represents the base command when called without any subcommands
@@ -44,8 +49,18 @@ to quickly create a Cobra application.`,
 	// Uncomment the following line if your bare application
 	// has an action associated with it:
 	//	Run: func(cmd *cobra.Command, args []string) { },
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if olDir == "" || isReadOnlyCommand(cmd) {
+			return nil
+		}
+		return selectFrontend()
+	},
 }
 
+// olDir is the .openlambda directory found by findOlDir in init, or "" if
+// none was found.
+var olDir string
+
 // Execute adds all child commands to the root command sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
@@ -63,29 +78,75 @@ func init() {
 	// will be global for your application.
 
 	RootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.experimental.yaml)")
-	RootCmd.PersistentFlags().StringVar(&frontendStr, "frontend", "effe", "OpenLambda frontend framework (default is effe)")
+	RootCmd.PersistentFlags().StringVar(&frontendStr, "frontend", "", "OpenLambda frontend framework (default is .openlambda/config's default_frontend, then last-used, then effe)")
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
 	RootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 
+	// frontends compiled directly into this binary are registered up
+	// front; plugins (ol-frontend-* on $PATH or $OL_PLUGINS) are
+	// discovered lazily once we know there's an .openlambda dir to root
+	// them in.
+	registry.RegisterCompiled("effe", effe.NewFrontEnd)
+
 	// find the .openlambda folder or warn user if not found
-	olDir := findOlDir()
+	olDir = findOlDir()
 	if olDir == "" {
 		fmt.Printf("WARNING: no .openlambda directory found (Have you called %s init yet?)\n\n", os.Args[0])
 		return
 	}
 	fmt.Printf("using .openlambda at %s\n", olDir)
 
-	// Here we select the frontend, based on user configs found from above
-	switch frontendStr {
-	case "effe":
-		fe = effe.NewFrontEnd(olDir)
-	default:
-		fmt.Println("frontend %s is unsupported\n")
-		os.Exit(1)
+	if errs := registry.Discover(); len(errs) > 0 {
+		for _, err := range errs {
+			fmt.Printf("warning: frontend plugin discovery: %v\n", err)
+		}
 	}
 }
 
+// selectFrontend resolves which frontend to use, in priority order:
+// an explicit --frontend flag, the "default_frontend" key in .openlambda/config,
+// the last frontend used in this .openlambda dir, and finally the
+// compiled default (effe). The chosen name is persisted back to
+// .openlambda/config as the new last-used frontend.
+//
+// Plugin-backed frontends are rejected here rather than silently leaving
+// fe unset: no subcommand in this CLI drives a frontend through
+// Plugin.Invoke yet, so selecting one would only defer the failure to
+// whatever later tries to use fe.
+func selectFrontend() error {
+	name := frontendStr
+	if name == "" {
+		name = readFrontendConfig(olDir)
+	}
+	if name == "" {
+		name = defaultFrontend
+	}
+
+	newFe, err := registry.New(name, olDir)
+	if err != nil {
+		if registry.IsPlugin(name) {
+			return fmt.Errorf("frontend %q is a plugin; plugin dispatch isn't wired into any command yet", name)
+		}
+		return err
+	}
+	fe = newFe
+
+	return writeFrontendConfig(olDir, name)
+}
+
+// isReadOnlyCommand reports whether cmd merely inspects state (e.g.
+// `frontends list`) rather than driving a frontend, so PersistentPreRunE
+// can skip resolving -- and persisting -- a frontend selection for it.
+func isReadOnlyCommand(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c == frontendsCmd {
+			return true
+		}
+	}
+	return false
+}
+
 func findOlDir() string {
 	wd, err := os.Getwd()
 	if err != nil {
@@ -136,4 +197,4 @@ func initConfig() {
 	if err := viper.ReadInConfig(); err == nil {
 		fmt.Println("Using config file:", viper.ConfigFileUsed())
 	}
-}
\ No newline at end of file
+}
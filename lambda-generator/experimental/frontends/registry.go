@@ -0,0 +1,193 @@
+// Copyright © 2016 NAME HERE <EMAIL ADDRESS>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frontends
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// PluginPrefix is the executable name prefix the registry scans $PATH and
+// $OL_PLUGINS for, analogous to how the docker CLI discovers out-of-process
+// sub-builders like buildx via a `docker-buildx` executable.
+const PluginPrefix = "ol-frontend-"
+
+// Metadata describes a frontend's identity, as reported by a plugin's
+// `metadata` subcommand (JSON on stdout).
+type Metadata struct {
+	Name    string          `json:"name"`
+	Version string          `json:"version"`
+	Schema  json.RawMessage `json:"schema"`
+}
+
+// Factory builds a compiled-in FrontEnd rooted at olDir.
+type Factory func(olDir string) FrontEnd
+
+// Plugin is a frontend discovered as an out-of-process `ol-frontend-*`
+// executable rather than one compiled into this binary.
+type Plugin struct {
+	Metadata Metadata
+	Path     string
+}
+
+// Registry tracks every frontend known to the CLI: compiled-in frontends
+// (like effe) registered directly by name, and plugins discovered on disk.
+type Registry struct {
+	compiled map[string]Factory
+	plugins  map[string]Plugin
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		compiled: make(map[string]Factory),
+		plugins:  make(map[string]Plugin),
+	}
+}
+
+// RegisterCompiled adds a frontend that is linked into this binary.
+func (r *Registry) RegisterCompiled(name string, new Factory) {
+	r.compiled[name] = new
+}
+
+// Discover scans $PATH, then $OL_PLUGINS, for executables named
+// `ol-frontend-*`, queries each for metadata, and adds the ones that
+// respond to the registry. A plugin that fails to respond is skipped
+// rather than treated as fatal, so one broken plugin can't block startup;
+// errors are returned for the caller to log.
+func (r *Registry) Discover() []error {
+	var errs []error
+	for _, dir := range pluginDirs() {
+		matches, err := filepath.Glob(filepath.Join(dir, PluginPrefix+"*"))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, path := range matches {
+			info, err := os.Stat(path)
+			if err != nil || info.IsDir() || info.Mode()&0111 == 0 {
+				continue
+			}
+
+			name := strings.TrimPrefix(filepath.Base(path), PluginPrefix)
+			meta, err := queryMetadata(path)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %v", path, err))
+				continue
+			}
+			if meta.Name == "" {
+				meta.Name = name
+			}
+			r.plugins[meta.Name] = Plugin{Metadata: meta, Path: path}
+		}
+	}
+	return errs
+}
+
+// pluginDirs returns $PATH entries followed by $OL_PLUGINS entries, so a
+// plugin under $OL_PLUGINS shadows a same-named one found on $PATH.
+func pluginDirs() []string {
+	dirs := filepath.SplitList(os.Getenv("PATH"))
+	if extra := os.Getenv("OL_PLUGINS"); extra != "" {
+		dirs = append(dirs, filepath.SplitList(extra)...)
+	}
+	return dirs
+}
+
+// queryMetadata invokes a plugin's `metadata` subcommand and decodes its
+// JSON response from stdout.
+func queryMetadata(path string) (Metadata, error) {
+	cmd := exec.Command(path, "metadata")
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		return Metadata{}, err
+	}
+
+	var meta Metadata
+	if err := json.Unmarshal(stdout.Bytes(), &meta); err != nil {
+		return Metadata{}, fmt.Errorf("invalid metadata: %v", err)
+	}
+	return meta, nil
+}
+
+// Names returns every known frontend name (compiled and plugin), sorted
+// for stable `frontends list` output.
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.compiled)+len(r.plugins))
+	for name := range r.compiled {
+		names = append(names, name)
+	}
+	for name := range r.plugins {
+		if _, ok := r.compiled[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsPlugin reports whether name resolves to a discovered plugin rather
+// than a compiled-in frontend.
+func (r *Registry) IsPlugin(name string) bool {
+	_, ok := r.plugins[name]
+	_, compiled := r.compiled[name]
+	return ok && !compiled
+}
+
+// Plugin returns the discovered plugin registered under name, if any.
+func (r *Registry) Plugin(name string) (Plugin, bool) {
+	p, ok := r.plugins[name]
+	return p, ok
+}
+
+// New resolves name to a compiled-in FrontEnd rooted at olDir. It returns
+// an error for plugin-backed frontends; callers that need to drive a
+// plugin should dispatch to it over JSON-over-stdin/stdout instead (see
+// Plugin.Invoke) rather than go through this constructor.
+func (r *Registry) New(name, olDir string) (FrontEnd, error) {
+	if new, ok := r.compiled[name]; ok {
+		return new(olDir), nil
+	}
+	if _, ok := r.plugins[name]; ok {
+		return nil, fmt.Errorf("frontend %q is a plugin; dispatch via Plugin.Invoke instead of New", name)
+	}
+	return nil, fmt.Errorf("frontend %q is unsupported", name)
+}
+
+// Invoke drives this plugin with request, writing it as JSON on the
+// plugin's stdin and decoding its JSON response from stdout -- the
+// dispatch New's doc comment promises for plugin-backed frontends.
+func (p Plugin) Invoke(request interface{}) (json.RawMessage, error) {
+	in, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command(p.Path, "invoke")
+	cmd.Stdin = bytes.NewReader(in)
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s invoke: %v", p.Path, err)
+	}
+	return json.RawMessage(stdout.Bytes()), nil
+}
@@ -0,0 +1,147 @@
+// Package console provides a small pseudo-terminal wrapper for attaching
+// interactively to a sandbox's stdio, in the spirit of (and much smaller
+// than) containerd/console: a Console is split into a master half the
+// sandbox manager drives and a slave half handed to the sandboxed
+// process as fd 0/1/2.
+package console
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+// WinSize is a terminal's dimensions, as reported via
+// TIOCGWINSZ/TIOCSWINSZ.
+type WinSize struct {
+	Rows uint16
+	Cols uint16
+	X    uint16
+	Y    uint16
+}
+
+// Console is one half of a pseudo-terminal pair.
+type Console interface {
+	io.ReadWriteCloser
+
+	// Resize sets this console's window size.
+	Resize(ws WinSize) error
+
+	// ResizeFrom copies another console's window size onto this one, e.g.
+	// to mirror an attach client's terminal size onto the sandbox's PTY.
+	ResizeFrom(c Console) error
+
+	// SetRaw puts this console into raw mode (no echo, no line buffering,
+	// no signal generation), the mode an interactive attach session
+	// wants.
+	SetRaw() error
+
+	// Fd returns the underlying file descriptor.
+	Fd() uintptr
+}
+
+// NewPty opens a fresh pseudo-terminal pair via /dev/ptmx, returning the
+// master side (read/written by the attach/logs plumbing) and the slave
+// side (passed to the sandboxed process as its stdio).
+func NewPty() (master Console, slave Console, err error) {
+	m, err := os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := unlockpt(m); err != nil {
+		m.Close()
+		return nil, nil, err
+	}
+
+	spath, err := ptsname(m)
+	if err != nil {
+		m.Close()
+		return nil, nil, err
+	}
+
+	s, err := os.OpenFile(spath, os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		m.Close()
+		return nil, nil, err
+	}
+
+	return &console{f: m}, &console{f: s}, nil
+}
+
+type console struct {
+	f  *os.File
+	mu sync.Mutex
+}
+
+func (c *console) Read(p []byte) (int, error)  { return c.f.Read(p) }
+func (c *console) Write(p []byte) (int, error) { return c.f.Write(p) }
+func (c *console) Close() error                { return c.f.Close() }
+func (c *console) Fd() uintptr                 { return c.f.Fd() }
+
+func (c *console) Resize(ws WinSize) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return ioctl(c.f.Fd(), syscall.TIOCSWINSZ, unsafe.Pointer(&ws))
+}
+
+func (c *console) ResizeFrom(other Console) error {
+	var ws WinSize
+	if err := ioctl(other.Fd(), syscall.TIOCGWINSZ, unsafe.Pointer(&ws)); err != nil {
+		return err
+	}
+	return c.Resize(ws)
+}
+
+func (c *console) SetRaw() error {
+	termios, err := tcgetattr(c.f.Fd())
+	if err != nil {
+		return err
+	}
+
+	raw := *termios
+	raw.Iflag &^= syscall.IGNBRK | syscall.BRKINT | syscall.PARMRK | syscall.ISTRIP |
+		syscall.INLCR | syscall.IGNCR | syscall.ICRNL | syscall.IXON
+	raw.Oflag &^= syscall.OPOST
+	raw.Lflag &^= syscall.ECHO | syscall.ECHONL | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+	raw.Cflag &^= syscall.CSIZE | syscall.PARENB
+	raw.Cflag |= syscall.CS8
+
+	return tcsetattr(c.f.Fd(), &raw)
+}
+
+func ioctl(fd uintptr, req uintptr, arg unsafe.Pointer) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, req, uintptr(arg))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+func unlockpt(f *os.File) error {
+	var unlock int32
+	return ioctl(f.Fd(), syscall.TIOCSPTLCK, unsafe.Pointer(&unlock))
+}
+
+func ptsname(f *os.File) (string, error) {
+	var n int32
+	if err := ioctl(f.Fd(), syscall.TIOCGPTN, unsafe.Pointer(&n)); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("/dev/pts/%d", n), nil
+}
+
+func tcgetattr(fd uintptr) (*syscall.Termios, error) {
+	var t syscall.Termios
+	if err := ioctl(fd, syscall.TCGETS, unsafe.Pointer(&t)); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func tcsetattr(fd uintptr, t *syscall.Termios) error {
+	return ioctl(fd, syscall.TCSETS, unsafe.Pointer(t))
+}
@@ -0,0 +1,127 @@
+package console
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ringCapacity bounds how much history Mux keeps in memory for Tail;
+// older bytes are overwritten once the ring fills, while the on-disk log
+// file keeps the full history for anything that wants more than Mux can
+// hold in RAM.
+const ringCapacity = 256 * 1024
+
+// Mux fans a single stream of sandbox output out to a log file, an
+// in-memory ring buffer, and any number of live attach/logs subscribers, so `/attach` and
+// `/logs` can share the same plumbing a sandbox's console is already
+// writing to.
+type Mux struct {
+	mu   sync.Mutex
+	subs map[chan []byte]struct{}
+
+	log *os.File
+
+	ring      [ringCapacity]byte
+	ringStart int
+	ringLen   int
+}
+
+// NewMux creates a Mux that appends everything it sees to logPath (e.g.
+// "${scratchDir}/logs/stdout.log"), creating parent directories as
+// needed.
+func NewMux(logPath string) (*Mux, error) {
+	if err := os.MkdirAll(filepath.Dir(logPath), 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &Mux{subs: make(map[chan []byte]struct{}), log: f}, nil
+}
+
+// Run reads from src until it's exhausted, writing every chunk to the log
+// file, the in-memory ring, and any live subscribers. It's meant to run
+// in its own goroutine for the lifetime of the sandbox's console.
+func (m *Mux) Run(src io.Reader) {
+	buf := make([]byte, 4096)
+	for {
+		n, err := src.Read(buf)
+		if n > 0 {
+			m.publish(buf[:n])
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (m *Mux) publish(p []byte) {
+	chunk := append([]byte(nil), p...)
+
+	m.mu.Lock()
+	m.log.Write(chunk)
+	m.appendRing(chunk)
+	for ch := range m.subs {
+		select {
+		case ch <- chunk:
+		default:
+			// slow subscriber; drop rather than block the sandbox's output
+		}
+	}
+	m.mu.Unlock()
+}
+
+func (m *Mux) appendRing(p []byte) {
+	for _, b := range p {
+		m.ring[(m.ringStart+m.ringLen)%ringCapacity] = b
+		if m.ringLen < ringCapacity {
+			m.ringLen++
+		} else {
+			m.ringStart = (m.ringStart + 1) % ringCapacity
+		}
+	}
+}
+
+// Tail returns up to the last n bytes this Mux still has buffered in
+// memory. n <= 0 returns everything buffered.
+func (m *Mux) Tail(n int) []byte {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if n <= 0 || n > m.ringLen {
+		n = m.ringLen
+	}
+	start := (m.ringStart + m.ringLen - n) % ringCapacity
+
+	out := make([]byte, n)
+	for i := 0; i < n; i++ {
+		out[i] = m.ring[(start+i)%ringCapacity]
+	}
+	return out
+}
+
+// Subscribe registers a new live listener; callers must Unsubscribe when
+// done to avoid leaking the channel.
+func (m *Mux) Subscribe() chan []byte {
+	ch := make(chan []byte, 64)
+	m.mu.Lock()
+	m.subs[ch] = struct{}{}
+	m.mu.Unlock()
+	return ch
+}
+
+// Unsubscribe removes and closes a channel returned by Subscribe.
+func (m *Mux) Unsubscribe(ch chan []byte) {
+	m.mu.Lock()
+	delete(m.subs, ch)
+	m.mu.Unlock()
+	close(ch)
+}
+
+// Close closes the underlying log file.
+func (m *Mux) Close() error {
+	return m.log.Close()
+}
@@ -0,0 +1,77 @@
+package sandbox
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// DeviceSpec describes a single host device node to bind or mknod into a
+// sandbox's /dev, either because the caller asked for it explicitly or
+// because it was discovered while enumerating host devices for a
+// privileged sandbox. A sandbox only actually gets these once its
+// Sandbox.SetDevices is called; Create alone just records the request.
+type DeviceSpec struct {
+	Path        string `json:"path"`
+	Permissions string `json:"permissions"` // e.g. "rwm"
+	Major       uint32 `json:"major"`
+	Minor       uint32 `json:"minor"`
+	Mode        uint32 `json:"mode"`
+	Uid         uint32 `json:"uid"`
+	Gid         uint32 `json:"gid"`
+}
+
+// EnumerateHostDevices walks the top level of /dev and returns a
+// DeviceSpec for every character or block device found there, with full
+// "rwm" permissions -- the same thing a container runtime does when a
+// container is marked privileged: inherit every host device as-is rather
+// than an explicit allowlist.
+func EnumerateHostDevices() ([]DeviceSpec, error) {
+	entries, err := os.ReadDir("/dev")
+	if err != nil {
+		return nil, err
+	}
+
+	var devices []DeviceSpec
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			// a device node can disappear between ReadDir and Stat; skip
+			// it rather than failing the whole enumeration.
+			continue
+		}
+
+		mode := info.Mode()
+		if mode&(os.ModeDevice) == 0 {
+			continue
+		}
+
+		sys, ok := info.Sys().(*syscall.Stat_t)
+		if !ok {
+			continue
+		}
+
+		major, minor := deviceNumbers(uint64(sys.Rdev))
+		devices = append(devices, DeviceSpec{
+			Path:        filepath.Join("/dev", entry.Name()),
+			Permissions: "rwm",
+			Major:       major,
+			Minor:       minor,
+			Mode:        uint32(mode.Perm()),
+			Uid:         sys.Uid,
+			Gid:         sys.Gid,
+		})
+	}
+
+	return devices, nil
+}
+
+// deviceNumbers splits a raw dev_t into its major/minor components using
+// the 32-bit encoding the kernel's new_decode_dev() reads: a 12-bit major
+// in bits 8-19, and a 20-bit minor in bits 0-7 and 20-31. Bits above 31
+// are ignored.
+func deviceNumbers(rdev uint64) (major, minor uint32) {
+	major = uint32((rdev >> 8) & 0xfff)
+	minor = uint32((rdev & 0xff) | ((rdev >> 12) & 0xfff00))
+	return major, minor
+}
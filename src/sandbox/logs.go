@@ -0,0 +1,15 @@
+package sandbox
+
+// LogsOpts controls how Sandbox.Logs replays a sandbox's ring-buffered
+// stdio history. There's no Since: the ring (console.Mux) stores raw
+// bytes with no per-byte timestamps, so "drop history older than T"
+// isn't something this buffer can answer.
+type LogsOpts struct {
+	// Follow keeps the returned reader open and streams new output as it
+	// arrives, instead of closing once history has been replayed.
+	Follow bool
+
+	// Tail limits replay to the last N bytes of history; 0 means replay
+	// everything the ring buffer still has.
+	Tail int
+}
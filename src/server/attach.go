@@ -0,0 +1,204 @@
+package server
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/open-lambda/open-lambda/ol/sandbox"
+	"github.com/open-lambda/open-lambda/ol/sandbox/console"
+)
+
+// Stream IDs for the attach/logs framed protocol: 1 byte stream-id, 4
+// byte big-endian length, then payload. A sandbox has a single PTY, so
+// its stdout and stderr are already merged into one stream by the time
+// they reach the Mux -- streamStdout is the only one Attach/Logs ever
+// emit. streamStderr exists only on the client->server side, so a client
+// can still label what it writes without SOCK needing to tell the
+// streams apart on the way out.
+const (
+	streamStdout  byte = 1
+	streamStderr  byte = 2
+	streamControl byte = 3
+)
+
+var attachUpgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// controlMsg is the JSON payload carried on the control stream; today the
+// only message an attach client sends is a TTY resize.
+type controlMsg struct {
+	Type string          `json:"type"`
+	Size console.WinSize `json:"size,omitempty"`
+}
+
+func encodeFrame(stream byte, payload []byte) []byte {
+	buf := make([]byte, 5+len(payload))
+	buf[0] = stream
+	binary.BigEndian.PutUint32(buf[1:5], uint32(len(payload)))
+	copy(buf[5:], payload)
+	return buf
+}
+
+func decodeFrame(data []byte) (stream byte, payload []byte, err error) {
+	if len(data) < 5 {
+		return 0, nil, fmt.Errorf("frame shorter than the 5-byte header")
+	}
+	n := binary.BigEndian.Uint32(data[1:5])
+	if int(n) != len(data)-5 {
+		return 0, nil, fmt.Errorf("frame length mismatch: header says %d, got %d", n, len(data)-5)
+	}
+	return data[0], data[5:], nil
+}
+
+// Attach upgrades an /attach/{id} request to a WebSocket and relays the
+// sandbox's console both ways: its output is framed onto the stdout
+// stream, frames the client sends on the stdout or stderr stream ID are
+// written to the console as input, and control frames are applied as a
+// resize. Output is read from the same
+// Mux /logs uses, not from the console directly -- attachConsole's
+// mux.Run(master) goroutine is already the PTY master's one and only
+// reader, so a second direct Read here would race it for bytes.
+func (s *SOCKServer) Attach(w http.ResponseWriter, r *http.Request, id string) {
+	c := s.GetSandbox(id)
+	if c == nil {
+		http.Error(w, fmt.Sprintf("no sandbox found with ID '%s'", id), http.StatusNotFound)
+		return
+	}
+
+	term := c.Console()
+	if term == nil {
+		http.Error(w, "sandbox has no attachable console", http.StatusBadRequest)
+		return
+	}
+
+	output, err := c.Logs(sandbox.LogsOpts{Follow: true})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("attach %s: %v", id, err), http.StatusInternalServerError)
+		return
+	}
+	defer output.Close()
+
+	conn, err := attachUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("attach %s: upgrade failed: %v", id, err)
+		return
+	}
+	defer conn.Close()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		buf := make([]byte, 4096)
+		for {
+			n, err := output.Read(buf)
+			if n > 0 {
+				if werr := conn.WriteMessage(websocket.BinaryMessage, encodeFrame(streamStdout, buf[:n])); werr != nil {
+					return
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			break
+		}
+
+		stream, payload, err := decodeFrame(data)
+		if err != nil {
+			log.Printf("attach %s: %v", id, err)
+			continue
+		}
+
+		switch stream {
+		case streamStdout, streamStderr:
+			term.Write(payload)
+		case streamControl:
+			var msg controlMsg
+			if err := json.Unmarshal(payload, &msg); err == nil && msg.Type == "resize" {
+				term.Resize(msg.Size)
+			}
+		}
+	}
+
+	// The client disconnected; don't block here waiting for done, which
+	// only closes once output.Read returns -- and the follow reader may
+	// stay open long after this client is gone. The deferred conn.Close()
+	// fails the output goroutine's next WriteMessage, which unblocks it.
+}
+
+// Logs serves /logs/{id}, replaying a sandbox's ring-buffered stdio
+// history (stdout and stderr merged, since the sandbox only has one PTY)
+// and, if ?follow=true, continuing to stream new output in the same
+// framing Attach uses.
+func (s *SOCKServer) Logs(w http.ResponseWriter, r *http.Request, id string) error {
+	c := s.GetSandbox(id)
+	if c == nil {
+		return fmt.Errorf("no sandbox found with ID '%s'", id)
+	}
+
+	opts, err := parseLogsOpts(r)
+	if err != nil {
+		return err
+	}
+
+	rc, err := c.Logs(opts)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	flusher, _ := w.(http.Flusher)
+	buf := make([]byte, 4096)
+	for {
+		n, err := rc.Read(buf)
+		if n > 0 {
+			if _, werr := w.Write(encodeFrame(streamStdout, buf[:n])); werr != nil {
+				return werr
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+func parseLogsOpts(r *http.Request) (sandbox.LogsOpts, error) {
+	q := r.URL.Query()
+
+	opts := sandbox.LogsOpts{
+		Follow: q.Get("follow") == "true",
+	}
+
+	if tail := q.Get("tail"); tail != "" {
+		n, err := strconv.Atoi(tail)
+		if err != nil {
+			return opts, fmt.Errorf("invalid tail: %v", err)
+		}
+		opts.Tail = n
+	}
+
+	if q.Get("since") != "" {
+		return opts, fmt.Errorf("since is not supported: the ring buffer stores raw bytes with no per-byte timestamps")
+	}
+
+	return opts, nil
+}
@@ -0,0 +1,157 @@
+package oci
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	"github.com/open-lambda/open-lambda/ol/sandbox"
+)
+
+// record is everything Adapter remembers about a sandbox it created, so
+// State can answer without going back to the pool.
+type record struct {
+	sandbox     sandbox.Sandbox
+	bundle      string
+	annotations map[string]string
+	status      string // "created", "running", "stopped"
+}
+
+// Adapter drives sandbox.SOCKPools using OCI runtime-spec bundles instead
+// of SOCKServer's native map[string]interface{} args, so crun/runc-aware
+// tooling can create and manage SOCK sandboxes directly.
+type Adapter struct {
+	cachePool   *sandbox.SOCKPool
+	handlerPool *sandbox.SOCKPool
+	getSandbox  func(id string) sandbox.Sandbox
+	scratchRoot string
+
+	records sync.Map // id -> *record
+
+	nextScratchId int64
+}
+
+// NewAdapter builds an Adapter that creates leaf sandboxes in
+// handlerPool and cache (Zygote) sandboxes in cachePool -- the same
+// split SOCKServer's native /create makes on its "leaf" arg -- resolving
+// parent-sandbox annotations via getSandbox (the same lookup SOCKServer
+// uses for its native /create), and rooting scratch dirs under
+// scratchRoot.
+func NewAdapter(cachePool, handlerPool *sandbox.SOCKPool, getSandbox func(id string) sandbox.Sandbox, scratchRoot string) *Adapter {
+	return &Adapter{
+		cachePool:   cachePool,
+		handlerPool: handlerPool,
+		getSandbox:  getSandbox,
+		scratchRoot: scratchRoot,
+	}
+}
+
+// Create translates bundle (a config.json) into sandbox.SOCKPool.Create
+// params, creates the sandbox, and returns its initial OCI state.
+func (a *Adapter) Create(bundle []byte) (*State, error) {
+	var spec Spec
+	if err := json.Unmarshal(bundle, &spec); err != nil {
+		return nil, fmt.Errorf("invalid OCI bundle: %v", err)
+	}
+
+	params, err := Translate(&spec)
+	if err != nil {
+		return nil, err
+	}
+
+	var parent sandbox.Sandbox
+	if params.ParentID != "" {
+		parent = a.getSandbox(params.ParentID)
+		if parent == nil {
+			return nil, fmt.Errorf("no sandbox found with ID '%s'", params.ParentID)
+		}
+	}
+
+	scratchId := fmt.Sprintf("oci-%d", atomic.AddInt64(&a.nextScratchId, 1))
+	scratchDir := filepath.Join(a.scratchRoot, scratchId)
+	if err := os.MkdirAll(scratchDir, 0777); err != nil {
+		return nil, err
+	}
+
+	pool := a.handlerPool
+	if !params.Leaf {
+		pool = a.cachePool
+	}
+	c, err := pool.Create(parent, params.Leaf, params.CodeDir, scratchDir, nil, false, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	a.records.Store(c.ID(), &record{
+		sandbox:     c,
+		bundle:      params.CodeDir,
+		annotations: spec.Annotations,
+		status:      "created",
+	})
+
+	return a.State(c.ID())
+}
+
+// Start transitions a created sandbox to running. SOCK sandboxes begin
+// running as soon as SOCKPool.Create returns, so this just updates the
+// OCI-visible status to match what SOCK already did.
+func (a *Adapter) Start(id string) error {
+	r, err := a.record(id)
+	if err != nil {
+		return err
+	}
+	r.status = "running"
+	return nil
+}
+
+// State returns the current OCI runtime state for id.
+func (a *Adapter) State(id string) (*State, error) {
+	r, err := a.record(id)
+	if err != nil {
+		return nil, err
+	}
+	return &State{
+		OCIVersion:  "1.0.3",
+		ID:          id,
+		Status:      r.status,
+		Bundle:      r.bundle,
+		Annotations: r.annotations,
+	}, nil
+}
+
+// Kill stops the sandbox backing id without destroying it, mirroring
+// `runc kill`.
+func (a *Adapter) Kill(id string) error {
+	r, err := a.record(id)
+	if err != nil {
+		return err
+	}
+	if err := r.sandbox.Pause(); err != nil {
+		return err
+	}
+	r.status = "stopped"
+	return nil
+}
+
+// Delete tears down the sandbox backing id and forgets it, mirroring
+// `runc delete`.
+func (a *Adapter) Delete(id string) error {
+	r, err := a.record(id)
+	if err != nil {
+		return err
+	}
+	r.sandbox.Destroy()
+	a.records.Delete(id)
+	return nil
+}
+
+func (a *Adapter) record(id string) (*record, error) {
+	val, ok := a.records.Load(id)
+	if !ok {
+		return nil, fmt.Errorf("no OCI sandbox found with ID '%s'", id)
+	}
+	return val.(*record), nil
+}
@@ -0,0 +1,107 @@
+// Package oci adapts SOCKServer's ad-hoc create/destroy protocol to the
+// subset of the OCI runtime-spec (v1.0.3+) that SOCK can actually satisfy,
+// so crun/runc-aware tooling and CRI shims can drive SOCK sandboxes
+// directly. SOCK sandboxes run the handler code under root.path as-is --
+// there's no entrypoint to override and no mount table to assemble --
+// so process.args/env/cwd and mounts are rejected rather than honored.
+package oci
+
+// Spec is the subset of an OCI runtime-spec config.json bundle this
+// adapter understands. Fields outside this subset are ignored; fields
+// that conflict with what SOCK can provide (seccomp profiles, arbitrary
+// capabilities, joining an existing namespace by path, memory limits,
+// cgroups paths) cause Translate to fail with
+// ErrUnsupportedOCIFeature.
+type Spec struct {
+	OCIVersion  string            `json:"ociVersion"`
+	Root        Root              `json:"root"`
+	Process     Process           `json:"process"`
+	Mounts      []Mount           `json:"mounts,omitempty"`
+	Linux       *Linux            `json:"linux,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// Root is the container's root filesystem, per the runtime-spec.
+type Root struct {
+	Path     string `json:"path"`
+	Readonly bool   `json:"readonly,omitempty"`
+}
+
+// Process describes the entrypoint the runtime-spec asks to run.
+type Process struct {
+	Args         []string           `json:"args"`
+	Env          []string           `json:"env,omitempty"`
+	Cwd          string             `json:"cwd,omitempty"`
+	Capabilities *LinuxCapabilities `json:"capabilities,omitempty"`
+}
+
+// LinuxCapabilities lists the capability sets a runtime-spec process asks
+// for. SOCK sandboxes don't model capabilities, so any non-empty set here
+// is rejected by Translate.
+type LinuxCapabilities struct {
+	Bounding  []string `json:"bounding,omitempty"`
+	Effective []string `json:"effective,omitempty"`
+	Permitted []string `json:"permitted,omitempty"`
+}
+
+// Mount is a runtime-spec mount entry. SOCK has no mechanism to honor any
+// mount beyond root.path (which becomes the sandbox's codeDir directly),
+// so Translate rejects a bundle that specifies any.
+type Mount struct {
+	Destination string   `json:"destination"`
+	Source      string   `json:"source,omitempty"`
+	Type        string   `json:"type,omitempty"`
+	Options     []string `json:"options,omitempty"`
+}
+
+// Linux is the linux-specific portion of the runtime-spec.
+type Linux struct {
+	Namespaces  []LinuxNamespace `json:"namespaces,omitempty"`
+	CgroupsPath string           `json:"cgroupsPath,omitempty"`
+	Resources   *LinuxResources  `json:"resources,omitempty"`
+	Seccomp     *LinuxSeccomp    `json:"seccomp,omitempty"`
+}
+
+// LinuxNamespace is a single namespace entry under linux.namespaces.
+type LinuxNamespace struct {
+	Type string `json:"type"`
+	Path string `json:"path,omitempty"`
+}
+
+// LinuxResources is the subset of linux.resources this adapter parses.
+// SOCK sizes sandboxes through its own mem pools, so Translate rejects a
+// bundle that sets memory.limit.
+type LinuxResources struct {
+	Memory *LinuxMemory `json:"memory,omitempty"`
+}
+
+// LinuxMemory is the subset of linux.resources.memory this adapter
+// parses; a set Limit is rejected by Translate.
+type LinuxMemory struct {
+	Limit *int64 `json:"limit,omitempty"`
+}
+
+// LinuxSeccomp is the runtime-spec seccomp profile. SOCK has no seccomp
+// layer of its own, so any profile here is rejected by Translate.
+type LinuxSeccomp struct {
+	DefaultAction string `json:"defaultAction"`
+}
+
+// State is the OCI runtime state JSON returned by GetSandbox/State.
+type State struct {
+	OCIVersion  string            `json:"ociVersion"`
+	ID          string            `json:"id"`
+	Status      string            `json:"status"`
+	Bundle      string            `json:"bundle"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// ParentAnnotation is the annotation key Translate reads to find the
+// parent sandbox ID a bundle should import-cache from.
+const ParentAnnotation = "openlambda.io/parent-sandbox"
+
+// LeafAnnotation is the annotation key Translate reads to decide whether
+// a bundle creates a leaf (handler) sandbox or a cache (Zygote) one, the
+// same leaf/cache distinction SOCKServer's native /create exposes via its
+// "leaf" arg. Absent or unrecognized values default to a leaf sandbox.
+const LeafAnnotation = "openlambda.io/leaf"
@@ -0,0 +1,78 @@
+package oci
+
+import "fmt"
+
+// ErrUnsupportedOCIFeature is returned when a bundle asks for a
+// runtime-spec feature SOCK has no analogue for.
+type ErrUnsupportedOCIFeature struct {
+	Feature string
+}
+
+func (e *ErrUnsupportedOCIFeature) Error() string {
+	return fmt.Sprintf("unsupported OCI feature: %s", e.Feature)
+}
+
+// CreateParams is what Translate reduces a Spec down to: everything
+// SOCKPool.Create actually needs.
+type CreateParams struct {
+	CodeDir  string
+	ParentID string
+	Leaf     bool
+}
+
+// Translate reduces the compatible subset of a runtime-spec bundle to the
+// parameters sandbox.SOCKPool.Create expects: root.path becomes the
+// sandbox's codeDir (SOCK binds it in directly rather than copying, so
+// there's no separate "rw layer" mount to thread through), the
+// openlambda.io/parent-sandbox annotation becomes the import-cache
+// parent, and the openlambda.io/leaf annotation selects the leaf/cache
+// pool. Anything the bundle asks for that SOCK can't provide is reported
+// as ErrUnsupportedOCIFeature rather than silently ignored.
+func Translate(spec *Spec) (CreateParams, error) {
+	if spec.Root.Path == "" {
+		return CreateParams{}, fmt.Errorf("root.path is required")
+	}
+
+	if len(spec.Process.Args) > 0 {
+		return CreateParams{}, &ErrUnsupportedOCIFeature{Feature: "process.args"}
+	}
+	if len(spec.Process.Env) > 0 {
+		return CreateParams{}, &ErrUnsupportedOCIFeature{Feature: "process.env"}
+	}
+	if spec.Process.Cwd != "" && spec.Process.Cwd != "/" {
+		return CreateParams{}, &ErrUnsupportedOCIFeature{Feature: "process.cwd"}
+	}
+
+	if caps := spec.Process.Capabilities; caps != nil {
+		if len(caps.Bounding) > 0 || len(caps.Effective) > 0 || len(caps.Permitted) > 0 {
+			return CreateParams{}, &ErrUnsupportedOCIFeature{Feature: "process.capabilities"}
+		}
+	}
+
+	if len(spec.Mounts) > 0 {
+		return CreateParams{}, &ErrUnsupportedOCIFeature{Feature: "mounts"}
+	}
+
+	if l := spec.Linux; l != nil {
+		if l.Seccomp != nil {
+			return CreateParams{}, &ErrUnsupportedOCIFeature{Feature: "linux.seccomp"}
+		}
+		if l.CgroupsPath != "" {
+			return CreateParams{}, &ErrUnsupportedOCIFeature{Feature: "linux.cgroupsPath"}
+		}
+		if res := l.Resources; res != nil && res.Memory != nil && res.Memory.Limit != nil {
+			return CreateParams{}, &ErrUnsupportedOCIFeature{Feature: "linux.resources.memory.limit"}
+		}
+		for _, ns := range l.Namespaces {
+			if ns.Path != "" {
+				return CreateParams{}, &ErrUnsupportedOCIFeature{Feature: "linux.namespaces[].path (joining an existing namespace)"}
+			}
+		}
+	}
+
+	return CreateParams{
+		CodeDir:  spec.Root.Path,
+		ParentID: spec.Annotations[ParentAnnotation],
+		Leaf:     spec.Annotations[LeafAnnotation] != "false",
+	}, nil
+}
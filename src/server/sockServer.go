@@ -1,6 +1,8 @@
 package server
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -11,9 +13,13 @@ import (
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/open-lambda/open-lambda/ol/common"
 	"github.com/open-lambda/open-lambda/ol/sandbox"
+	"github.com/open-lambda/open-lambda/ol/sandbox/console"
+	"github.com/open-lambda/open-lambda/ol/server/oci"
+	"github.com/open-lambda/open-lambda/ol/server/state"
 )
 
 type Handler func(http.ResponseWriter, []string, map[string]interface{}) error
@@ -26,6 +32,8 @@ type SOCKServer struct {
 	cachePool   *sandbox.SOCKPool
 	handlerPool *sandbox.SOCKPool
 	sandboxes   sync.Map
+	oci         *oci.Adapter
+	state       *state.Store
 }
 
 func (s *SOCKServer) GetSandbox(id string) sandbox.Sandbox {
@@ -60,23 +68,136 @@ func (s *SOCKServer) Create(w http.ResponseWriter, rsrc []string, args map[strin
 		}
 	}
 
+	privileged, devices, err := parsePrivilegeArgs(args)
+	if err != nil {
+		return err
+	}
+
 	// spin it up
 	scratchId := fmt.Sprintf("dir-%d", atomic.AddInt64(&nextScratchId, 1))
 	scratchDir := filepath.Join(common.Conf.Worker_dir, "scratch", scratchId)
 	if err := os.MkdirAll(scratchDir, 0777); err != nil {
 		panic(err)
 	}
-	c, err := pool.Create(parent, leaf, codeDir, scratchDir, nil)
+	c, err := pool.Create(parent, leaf, codeDir, scratchDir, nil, privileged, devices)
 	if err != nil {
 		return err
 	}
+	if len(devices) > 0 {
+		if err := c.SetDevices(devices); err != nil {
+			c.Destroy()
+			return err
+		}
+	}
+	if err := attachConsole(c, scratchDir); err != nil {
+		c.Destroy()
+		return err
+	}
 	s.sandboxes.Store(c.ID(), c)
 	log.Printf("Save ID '%s' to map\n", c.ID())
 
+	poolName := "cache"
+	if leaf {
+		poolName = "handler"
+	}
+	var parentID string
+	if parent != nil {
+		parentID = parent.ID()
+	}
+	if err := s.state.Put(state.Record{
+		ID:          c.ID(),
+		Pool:        poolName,
+		Parent:      parentID,
+		CodeDirHash: hashCodeDir(codeDir),
+		ScratchDir:  scratchDir,
+		CreatedAt:   time.Now(),
+		Status:      "created",
+	}); err != nil {
+		log.Printf("failed to persist state for sandbox '%s': %v", c.ID(), err)
+	}
+
 	w.Write([]byte(fmt.Sprintf("%v\n", c.ID())))
 	return nil
 }
 
+// attachConsole opens a PTY and a persistent Mux for c's stdio, rooting
+// the Mux's log file under scratchDir, and hands both to c so /attach and
+// /logs (see attach.go) have a real console and output history to serve.
+func attachConsole(c sandbox.Sandbox, scratchDir string) error {
+	master, slave, err := console.NewPty()
+	if err != nil {
+		return err
+	}
+
+	mux, err := console.NewMux(filepath.Join(scratchDir, "logs", "stdout.log"))
+	if err != nil {
+		master.Close()
+		slave.Close()
+		return err
+	}
+
+	if err := c.SetConsole(master, slave, mux); err != nil {
+		master.Close()
+		slave.Close()
+		mux.Close()
+		return err
+	}
+	go mux.Run(master)
+
+	return nil
+}
+
+// hashCodeDir fingerprints a sandbox's code layer for the state store by
+// hashing its codeDir path string; the directory's contents are not read,
+// and the path itself is not persisted.
+func hashCodeDir(codeDir string) string {
+	sum := sha256.Sum256([]byte(codeDir))
+	return hex.EncodeToString(sum[:])
+}
+
+// parsePrivilegeArgs reads the optional "privileged" and "devices" create
+// args and resolves them to the device list a sandbox should get: every
+// host device for a privileged sandbox, or just the explicitly requested
+// devices otherwise. It refuses privileged requests outright unless the
+// administrator opted in via common.Conf.AllowPrivileged.
+func parsePrivilegeArgs(args map[string]interface{}) (bool, []sandbox.DeviceSpec, error) {
+	privileged, _ := args["privileged"].(bool)
+	if privileged && !common.Conf.AllowPrivileged {
+		return false, nil, fmt.Errorf("privileged sandboxes are disabled (AllowPrivileged=false)")
+	}
+
+	if privileged {
+		devices, err := sandbox.EnumerateHostDevices()
+		if err != nil {
+			return false, nil, err
+		}
+		return true, devices, nil
+	}
+
+	raw, ok := args["devices"].([]interface{})
+	if !ok {
+		return false, nil, nil
+	}
+
+	devices := make([]sandbox.DeviceSpec, 0, len(raw))
+	for _, d := range raw {
+		m, ok := d.(map[string]interface{})
+		if !ok {
+			return false, nil, fmt.Errorf("devices entries must be objects with \"path\" and \"permissions\"")
+		}
+		path, _ := m["path"].(string)
+		if path == "" {
+			return false, nil, fmt.Errorf("devices entries require a non-empty \"path\"")
+		}
+		perms, _ := m["permissions"].(string)
+		if perms == "" {
+			perms = "rwm"
+		}
+		devices = append(devices, sandbox.DeviceSpec{Path: path, Permissions: perms})
+	}
+	return false, devices, nil
+}
+
 func (s *SOCKServer) Destroy(w http.ResponseWriter, rsrc []string, args map[string]interface{}) error {
 	c := s.GetSandbox(rsrc[0])
 	if c == nil {
@@ -85,6 +206,10 @@ func (s *SOCKServer) Destroy(w http.ResponseWriter, rsrc []string, args map[stri
 
 	c.Destroy()
 
+	if err := s.state.Delete(rsrc[0]); err != nil {
+		log.Printf("failed to remove state for sandbox '%s': %v", rsrc[0], err)
+	}
+
 	return nil
 }
 
@@ -106,6 +231,68 @@ func (s *SOCKServer) Unpause(w http.ResponseWriter, rsrc []string, args map[stri
 	return c.Unpause()
 }
 
+// OCICreate accepts a runtime-spec config.json bundle (already parsed
+// into args by HandleInternal) and creates a SOCK sandbox from it.
+func (s *SOCKServer) OCICreate(w http.ResponseWriter, rsrc []string, args map[string]interface{}) error {
+	bundle, err := json.Marshal(args)
+	if err != nil {
+		return err
+	}
+
+	state, err := s.oci.Create(bundle)
+	if err != nil {
+		return err
+	}
+
+	return writeOCIState(w, state)
+}
+
+// OCIStart marks the sandbox at rsrc[0] as running.
+func (s *SOCKServer) OCIStart(w http.ResponseWriter, rsrc []string, args map[string]interface{}) error {
+	if len(rsrc) < 1 {
+		return fmt.Errorf("no sandbox ID provided")
+	}
+	return s.oci.Start(rsrc[0])
+}
+
+// OCIState returns the OCI runtime state JSON for the sandbox at rsrc[0].
+func (s *SOCKServer) OCIState(w http.ResponseWriter, rsrc []string, args map[string]interface{}) error {
+	if len(rsrc) < 1 {
+		return fmt.Errorf("no sandbox ID provided")
+	}
+	state, err := s.oci.State(rsrc[0])
+	if err != nil {
+		return err
+	}
+	return writeOCIState(w, state)
+}
+
+// OCIKill pauses the sandbox at rsrc[0], mirroring `runc kill`.
+func (s *SOCKServer) OCIKill(w http.ResponseWriter, rsrc []string, args map[string]interface{}) error {
+	if len(rsrc) < 1 {
+		return fmt.Errorf("no sandbox ID provided")
+	}
+	return s.oci.Kill(rsrc[0])
+}
+
+// OCIDelete destroys the sandbox at rsrc[0] and forgets its OCI state,
+// mirroring `runc delete`.
+func (s *SOCKServer) OCIDelete(w http.ResponseWriter, rsrc []string, args map[string]interface{}) error {
+	if len(rsrc) < 1 {
+		return fmt.Errorf("no sandbox ID provided")
+	}
+	return s.oci.Delete(rsrc[0])
+}
+
+func writeOCIState(w http.ResponseWriter, state *oci.State) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	w.Write(data)
+	return nil
+}
+
 func (s *SOCKServer) Debug(w http.ResponseWriter, rsrc []string, args map[string]interface{}) error {
 	str := fmt.Sprintf(
 		"========\nCACHE SANDBOXES\n========\n%s========\nHANDLER SANDBOXES\n========\n%s",
@@ -152,6 +339,26 @@ func (s *SOCKServer) HandleInternal(w http.ResponseWriter, r *http.Request) erro
 		"debug":   s.Debug,
 	}
 
+	// the /oci/* routes are a separate namespace so crun/runc-aware
+	// tooling can drive SOCK via a runtime-spec-shaped API alongside the
+	// native routes above.
+	if rsrc[1] == "oci" {
+		if len(rsrc) < 3 {
+			return fmt.Errorf("no OCI op provided in URL")
+		}
+		ociRoutes := map[string]Handler{
+			"create": s.OCICreate,
+			"start":  s.OCIStart,
+			"state":  s.OCIState,
+			"kill":   s.OCIKill,
+			"delete": s.OCIDelete,
+		}
+		if h, ok := ociRoutes[rsrc[2]]; ok {
+			return h(w, rsrc[3:], args)
+		}
+		return fmt.Errorf("unknown OCI op %s", rsrc[2])
+	}
+
 	if h, ok := routes[rsrc[1]]; ok {
 		return h(w, rsrc[2:], args)
 	} else {
@@ -160,6 +367,23 @@ func (s *SOCKServer) HandleInternal(w http.ResponseWriter, r *http.Request) erro
 }
 
 func (s *SOCKServer) Handle(w http.ResponseWriter, r *http.Request) {
+	// /attach and /logs aren't part of the POST-a-JSON-body protocol the
+	// rest of this file implements: /attach upgrades to a WebSocket, and
+	// /logs streams a chunked response, so both are routed before
+	// HandleInternal ever reads the body.
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/attach/"):
+		s.Attach(w, r, strings.TrimPrefix(r.URL.Path, "/attach/"))
+		return
+	case strings.HasPrefix(r.URL.Path, "/logs/"):
+		if err := s.Logs(w, r, strings.TrimPrefix(r.URL.Path, "/logs/")); err != nil {
+			log.Printf("Request Handler Failed: %v", err)
+			w.WriteHeader(500)
+			w.Write([]byte(fmt.Sprintf("%v\n", err)))
+		}
+		return
+	}
+
 	if err := s.HandleInternal(w, r); err != nil {
 		log.Printf("Request Handler Failed: %v", err)
 		w.WriteHeader(500)
@@ -192,10 +416,29 @@ func NewSOCKServer() (*SOCKServer, error) {
 		return nil, err
 	}
 
+	backend, err := state.NewFileBackend(filepath.Join(common.Conf.Worker_dir, "state"))
+	if err != nil {
+		return nil, err
+	}
+	store, err := state.NewStore(backend)
+	if err != nil {
+		return nil, err
+	}
+	// Reconcile only prunes records whose scratch dir is gone; a record
+	// that survives just means the scratch dir is still there, not that
+	// its sandbox is running or reachable -- nothing repopulates
+	// s.sandboxes, so these records are metadata-only until their
+	// sandbox is destroyed and recreated through the normal /create path.
+	if live := store.Reconcile(); len(live) > 0 {
+		log.Printf("state store has %d sandbox record(s) left over from a previous run (not reattached)", len(live))
+	}
+
 	server := &SOCKServer{
 		cachePool:   cache,
 		handlerPool: handler,
+		state:       store,
 	}
+	server.oci = oci.NewAdapter(cache, handler, server.GetSandbox, filepath.Join(common.Conf.Worker_dir, "scratch"))
 
 	http.HandleFunc("/", server.Handle)
 
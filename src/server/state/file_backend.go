@@ -0,0 +1,91 @@
+package state
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// FileBackend persists one JSON record per sandbox, at dir/{id}.json.
+// Records are small (a few hundred bytes), so each is stored plain rather
+// than compressed: gzipping a single record gains nothing once its own
+// framing overhead is counted, and only amortizes across records sharing
+// a compression window, which per-file storage doesn't give us.
+type FileBackend struct {
+	dir string
+}
+
+// NewFileBackend creates a FileBackend rooted at dir, creating it if
+// necessary.
+func NewFileBackend(dir string) (*FileBackend, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &FileBackend{dir: dir}, nil
+}
+
+func (b *FileBackend) path(id string) string {
+	return filepath.Join(b.dir, id+".json")
+}
+
+// Put writes (or overwrites) r's record, via a temp-file-then-rename so a
+// crash mid-write can't leave a truncated record behind.
+func (b *FileBackend) Put(r Record) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	tmp := b.path(r.ID) + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, b.path(r.ID))
+}
+
+// Delete removes id's record, if present; deleting an already-absent
+// record is not an error.
+func (b *FileBackend) Delete(id string) error {
+	err := os.Remove(b.path(id))
+	if err != nil && os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// List decodes every record under dir. A record that fails to decode is
+// skipped rather than failing the whole reload, so one corrupt file can't
+// block a worker from starting.
+func (b *FileBackend) List() ([]Record, error) {
+	entries, err := ioutil.ReadDir(b.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []Record
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		r, err := b.read(filepath.Join(b.dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		records = append(records, r)
+	}
+	return records, nil
+}
+
+func (b *FileBackend) read(path string) (Record, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Record{}, err
+	}
+
+	var r Record
+	if err := json.Unmarshal(data, &r); err != nil {
+		return Record{}, err
+	}
+	return r, nil
+}
@@ -0,0 +1,33 @@
+// Package state persists SOCKServer's sandbox metadata so a worker
+// restart doesn't lose every record of what it had created. sync.Map
+// alone (what SOCKServer used to rely on) doesn't survive a restart, so a
+// sandbox's code layer, parent, and scratch dir would otherwise be gone
+// without a trace after one. This package only remembers that metadata;
+// it doesn't reattach to or rebuild any sandbox -- see Store.Reconcile.
+package state
+
+import "time"
+
+// Record is everything worth remembering about a sandbox across a
+// restart: enough to tell whether its scratch dir still exists
+// (Store.Reconcile) and, if something else someday needs it, which
+// sandbox was its parent.
+type Record struct {
+	ID          string    `json:"id"`
+	Pool        string    `json:"pool"`
+	Parent      string    `json:"parent,omitempty"`
+	CodeDirHash string    `json:"code_dir_hash"`
+	ScratchDir  string    `json:"scratch_dir"`
+	CreatedAt   time.Time `json:"created_at"`
+	Status      string    `json:"status"`
+}
+
+// Backend persists Records keyed by sandbox ID. Implementations may be
+// local-filesystem-, etcd-, or redis-backed so deployments can share
+// state across workers instead of each worker only knowing about its own
+// sandboxes.
+type Backend interface {
+	Put(r Record) error
+	Delete(id string) error
+	List() ([]Record, error)
+}
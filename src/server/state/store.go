@@ -0,0 +1,97 @@
+package state
+
+import (
+	"os"
+	"sync"
+)
+
+// Store caches every Record a Backend knows about in memory, so looking
+// one up doesn't mean re-reading it from disk (or from etcd/redis) on
+// every call.
+type Store struct {
+	backend Backend
+
+	mu    sync.RWMutex
+	cache map[string]Record
+}
+
+// NewStore loads every record backend already has and wraps it in a
+// Store, so the in-memory cache starts warm.
+func NewStore(backend Backend) (*Store, error) {
+	records, err := backend.List()
+	if err != nil {
+		return nil, err
+	}
+
+	cache := make(map[string]Record, len(records))
+	for _, r := range records {
+		cache[r.ID] = r
+	}
+
+	return &Store{backend: backend, cache: cache}, nil
+}
+
+// Put persists r and updates the in-memory cache.
+func (s *Store) Put(r Record) error {
+	if err := s.backend.Put(r); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.cache[r.ID] = r
+	s.mu.Unlock()
+	return nil
+}
+
+// Delete removes id from both the backend and the in-memory cache.
+func (s *Store) Delete(id string) error {
+	if err := s.backend.Delete(id); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	delete(s.cache, id)
+	s.mu.Unlock()
+	return nil
+}
+
+// Get returns the cached record for id, if any.
+func (s *Store) Get(id string) (Record, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	r, ok := s.cache[id]
+	return r, ok
+}
+
+// List returns every record currently cached.
+func (s *Store) List() []Record {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]Record, 0, len(s.cache))
+	for _, r := range s.cache {
+		out = append(out, r)
+	}
+	return out
+}
+
+// Reconcile drops any cached record whose scratch dir no longer exists on
+// disk -- e.g. because the worker crashed before it could clean up after
+// itself -- and returns whatever records survived. Call this once after
+// NewStore, before trusting the cache to reflect reality.
+func (s *Store) Reconcile() []Record {
+	s.mu.Lock()
+	var stale []string
+	for id, r := range s.cache {
+		if _, err := os.Stat(r.ScratchDir); err != nil {
+			stale = append(stale, id)
+		}
+	}
+	for _, id := range stale {
+		delete(s.cache, id)
+	}
+	s.mu.Unlock()
+
+	for _, id := range stale {
+		s.backend.Delete(id)
+	}
+
+	return s.List()
+}